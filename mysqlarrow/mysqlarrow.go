@@ -0,0 +1,139 @@
+// Package mysqlarrow provides functions for reading MySQL query results into Apache Arrow records.
+// It is kept apart from the go-mysql client, so only the programs that use it depend on Arrow.
+package mysqlarrow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/go-mysql-org/go-mysql/client"
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// ExecuteArrow executes a query with the text protocol and returns its result set as one
+// Arrow record, which is filled while the rows are streamed from the server. The caller must
+// call Release on the record. See Schema for the types of the columns.
+func ExecuteArrow(c *client.Conn, command string) (arrow.Record, error) {
+	var record arrow.Record
+	err := ExecuteArrowBatches(c, command, 0, func(r arrow.Record) error {
+		r.Retain()
+		record = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// ExecuteArrowBatches executes a query with the text protocol and calls fn with a record for
+// every batchSize rows of the result set, while the rows are streamed from the server. The last
+// record has the remaining rows, and a result set without rows gives one empty record. When
+// batchSize is 0, all rows are in one record.
+//
+// The records are released when fn returns, fn must call Retain to keep one. When fn returns an
+// error, or a value can not be converted, the remaining rows are read and discarded, so the
+// connection can still be used, and the error is returned.
+func ExecuteArrowBatches(c *client.Conn, command string, batchSize int, fn func(arrow.Record) error) error {
+	if batchSize < 0 {
+		return fmt.Errorf("invalid batch size %d", batchSize)
+	}
+
+	var (
+		builder *rowBuilder
+		rows    int
+		batches int
+		stopErr error
+	)
+	defer func() {
+		if builder != nil {
+			builder.Release()
+		}
+	}()
+
+	// flush passes the rows that were appended so far to fn
+	flush := func() error {
+		record := builder.NewRecord()
+		defer record.Release()
+		rows = 0
+		batches++
+		return fn(record)
+	}
+
+	var result mysql.Result
+	err := c.ExecuteSelectStreaming(command, &result, func(row []mysql.FieldValue) error {
+		if err := builder.appendRow(row); err != nil {
+			stopErr = err
+			return mysql.ErrStopStreaming
+		}
+		rows++
+		if rows == batchSize {
+			if err := flush(); err != nil {
+				stopErr = err
+				return mysql.ErrStopStreaming
+			}
+		}
+		return nil
+	}, func(r *mysql.Result) error {
+		if builder != nil {
+			stopErr = errors.New("the query returned more than one result set")
+			return mysql.ErrStopStreaming
+		}
+		var err error
+		if builder, err = newRowBuilder(memory.DefaultAllocator, r.Fields); err != nil {
+			stopErr = err
+			return mysql.ErrStopStreaming
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if stopErr != nil {
+		return stopErr
+	}
+	if builder == nil {
+		return errors.New("the query did not return a result set")
+	}
+	if rows > 0 || batches == 0 {
+		return flush()
+	}
+	return nil
+}
+
+// rowBuilder appends the rows of a result set to the arrays of an Arrow record
+type rowBuilder struct {
+	*array.RecordBuilder
+	fields    []*mysql.Field
+	appenders []appender
+}
+
+func newRowBuilder(mem memory.Allocator, fields []*mysql.Field) (*rowBuilder, error) {
+	schema, err := Schema(fields)
+	if err != nil {
+		return nil, err
+	}
+	b := &rowBuilder{
+		RecordBuilder: array.NewRecordBuilder(mem, schema),
+		fields:        fields,
+		appenders:     make([]appender, len(fields)),
+	}
+	for i, f := range fields {
+		b.appenders[i] = newAppender(f, schema.Field(i).Type)
+	}
+	return b, nil
+}
+
+// appendRow appends the values of a row. After an error the columns have different lengths, so
+// no record can be built anymore.
+func (b *rowBuilder) appendRow(row []mysql.FieldValue) error {
+	for i := range row {
+		if err := b.appenders[i](b.Field(i), &row[i]); err != nil {
+			return fmt.Errorf("column %s: %w", b.fields[i].Name, err)
+		}
+	}
+	return nil
+}
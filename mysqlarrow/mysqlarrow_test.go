@@ -0,0 +1,169 @@
+package mysqlarrow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func field(name string, tp byte, flag uint16) *mysql.Field {
+	return &mysql.Field{Name: []byte(name), Type: tp, Flag: flag, Charset: 255}
+}
+
+func text(s string) mysql.FieldValue {
+	return mysql.NewFieldValue(mysql.FieldValueTypeString, 0, []byte(s))
+}
+
+func TestSchema(t *testing.T) {
+	price := field("price", mysql.MYSQL_TYPE_NEWDECIMAL, 0)
+	// DECIMAL(10,2) has a length of 12, with the sign and the decimal point
+	price.ColumnLength, price.Decimal = 12, 2
+	huge := field("huge", mysql.MYSQL_TYPE_NEWDECIMAL, mysql.UNSIGNED_FLAG)
+	huge.ColumnLength = 65
+	blob := field("data", mysql.MYSQL_TYPE_BLOB, 0)
+	blob.Charset = mysql.BINARY_COLLATION_ID
+
+	schema, err := Schema([]*mysql.Field{
+		field("id", mysql.MYSQL_TYPE_LONGLONG, mysql.NOT_NULL_FLAG|mysql.UNSIGNED_FLAG),
+		field("n", mysql.MYSQL_TYPE_LONG, 0),
+		price,
+		huge,
+		field("created", mysql.MYSQL_TYPE_DATETIME, 0),
+		field("name", mysql.MYSQL_TYPE_VAR_STRING, 0),
+		blob,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Uint64},
+		{Name: "n", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "price", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+		{Name: "huge", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "created", Type: &arrow.TimestampType{Unit: arrow.Microsecond}, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "data", Type: arrow.BinaryTypes.Binary, Nullable: true},
+	}
+	for i, f := range schema.Fields() {
+		if !f.Equal(want[i]) {
+			t.Fatalf("column %d: got %v, want %v", i, f, want[i])
+		}
+	}
+
+	if _, err := Schema([]*mysql.Field{field("x", 0x42, 0)}); err == nil {
+		t.Fatal("got no error for an unknown column type")
+	}
+}
+
+func TestAppendRows(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	price := field("price", mysql.MYSQL_TYPE_NEWDECIMAL, 0)
+	price.ColumnLength, price.Decimal = 12, 2
+	b, err := newRowBuilder(mem, []*mysql.Field{
+		field("id", mysql.MYSQL_TYPE_LONGLONG, mysql.UNSIGNED_FLAG),
+		price,
+		field("day", mysql.MYSQL_TYPE_DATE, 0),
+		field("at", mysql.MYSQL_TYPE_DATETIME, 0),
+		field("took", mysql.MYSQL_TYPE_TIME, 0),
+		field("name", mysql.MYSQL_TYPE_VAR_STRING, 0),
+		field("flags", mysql.MYSQL_TYPE_BIT, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release()
+
+	rows := [][]mysql.FieldValue{
+		{
+			mysql.NewFieldValue(mysql.FieldValueTypeUnsigned, 18446744073709551615, nil),
+			text("-12.50"),
+			text("2024-02-29"),
+			text("2024-02-29 13:14:15.123456"),
+			text("-838:59:59.5"),
+			text("alice"),
+			text("\x01\x02"),
+		},
+		{
+			mysql.NewFieldValue(mysql.FieldValueTypeUnsigned, 2, nil),
+			{Type: mysql.FieldValueTypeNull},
+			text("0000-00-00"),
+			text("0000-00-00 00:00:00"),
+			text("00:00:01"),
+			{Type: mysql.FieldValueTypeNull},
+			text("\x00"),
+		},
+	}
+	for _, row := range rows {
+		if err := b.appendRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record := b.NewRecord()
+	defer record.Release()
+
+	if n := record.NumRows(); n != 2 {
+		t.Fatalf("got %d rows, want 2", n)
+	}
+	if v := record.Column(0).(*array.Uint64).Value(0); v != 18446744073709551615 {
+		t.Fatalf("got the id %d", v)
+	}
+	prices := record.Column(1).(*array.Decimal128)
+	if v := prices.Value(0).ToString(2); v != "-12.50" || !prices.IsNull(1) {
+		t.Fatalf("got the prices %s and %v", v, prices.ValueStr(1))
+	}
+	days := record.Column(2).(*array.Date32)
+	if v := days.Value(0).ToTime(); !v.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) || !days.IsNull(1) {
+		t.Fatalf("got the days %v and %v, a zero date must be NULL", v, days.ValueStr(1))
+	}
+	at := record.Column(3).(*array.Timestamp)
+	if v := at.Value(0).ToTime(arrow.Microsecond); !v.Equal(time.Date(2024, 2, 29, 13, 14, 15, 123456000, time.UTC)) || !at.IsNull(1) {
+		t.Fatalf("got the times %v and %v", v, at.ValueStr(1))
+	}
+	took := record.Column(4).(*array.Duration)
+	if want := -(838*time.Hour + 59*time.Minute + 59*time.Second + 500*time.Millisecond); took.Value(0) != arrow.Duration(want.Microseconds()) || took.Value(1) != arrow.Duration(time.Second.Microseconds()) {
+		t.Fatalf("got the durations %d and %d", took.Value(0), took.Value(1))
+	}
+	names := record.Column(5).(*array.String)
+	if names.Value(0) != "alice" || !names.IsNull(1) {
+		t.Fatalf("got the names %q and %q", names.Value(0), names.ValueStr(1))
+	}
+	flags := record.Column(6).(*array.Uint64)
+	if flags.Value(0) != 0x0102 || flags.Value(1) != 0 {
+		t.Fatalf("got the flags %#x and %#x", flags.Value(0), flags.Value(1))
+	}
+}
+
+func TestAppendInvalidValue(t *testing.T) {
+	b, err := newRowBuilder(memory.NewGoAllocator(), []*mysql.Field{field("n", mysql.MYSQL_TYPE_LONG, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release()
+	if err := b.appendRow([]mysql.FieldValue{text("abc")}); err == nil {
+		t.Fatal("got no error for an integer column with the value abc")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"00:00:00":         0,
+		"12:34:56":         12*time.Hour + 34*time.Minute + 56*time.Second,
+		"-01:00:00.000001": -(time.Hour + time.Microsecond),
+		"838:59:59.123":    838*time.Hour + 59*time.Minute + 59*time.Second + 123*time.Millisecond,
+	} {
+		if got, err := parseTime(s); err != nil || got != want {
+			t.Fatalf("%s: got %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "12:34", "1:2:x", "00:00:00.1234567"} {
+		if _, err := parseTime(s); err == nil {
+			t.Fatalf("%s: got no error", s)
+		}
+	}
+}
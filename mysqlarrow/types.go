@@ -0,0 +1,347 @@
+package mysqlarrow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// maxDecimal128Precision is the largest precision of arrow.Decimal128Type
+const maxDecimal128Precision = 38
+
+// Schema returns the Arrow schema for the columns of a result set. The MySQL types are mapped
+// to these Arrow types:
+//
+//   - TINYINT, SMALLINT, MEDIUMINT, INT and BIGINT to Int8, Int16, Int32, Int32 and Int64, or to
+//     the Uint types of the same sizes when the column is UNSIGNED
+//   - YEAR to Uint16 and BIT to Uint64
+//   - FLOAT and DOUBLE to Float32 and Float64
+//   - DECIMAL to Decimal128 with the precision and scale of the column, or to String when the
+//     precision is larger than 38
+//   - DATE to Date32, DATETIME and TIMESTAMP to Timestamp in microseconds without a time zone,
+//     holding the time as it is shown in the time zone of the session
+//   - TIME to Duration in microseconds, since it can be negative or longer than a day
+//   - CHAR, VARCHAR, TEXT, ENUM, SET and JSON to String
+//   - BINARY, VARBINARY, BLOB, GEOMETRY, VECTOR and the strings with the binary character set to Binary
+//
+// A column is nullable unless it is NOT NULL. NULL values are stored in the validity bitmap of
+// the array, and so are zero dates like 0000-00-00, which have no Arrow value.
+func Schema(fields []*mysql.Field) (*arrow.Schema, error) {
+	arrowFields := make([]arrow.Field, len(fields))
+	for i, f := range fields {
+		tp, err := arrowType(f)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", f.Name, err)
+		}
+		arrowFields[i] = arrow.Field{
+			Name:     string(f.Name),
+			Type:     tp,
+			Nullable: f.Flag&mysql.NOT_NULL_FLAG == 0,
+		}
+	}
+	return arrow.NewSchema(arrowFields, nil), nil
+}
+
+// arrowType returns the Arrow type of the values of the column f
+func arrowType(f *mysql.Field) (arrow.DataType, error) {
+	unsigned := f.Flag&mysql.UNSIGNED_FLAG != 0
+	switch f.Type {
+	case mysql.MYSQL_TYPE_TINY:
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint8, nil
+		}
+		return arrow.PrimitiveTypes.Int8, nil
+	case mysql.MYSQL_TYPE_SHORT:
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint16, nil
+		}
+		return arrow.PrimitiveTypes.Int16, nil
+	case mysql.MYSQL_TYPE_INT24, mysql.MYSQL_TYPE_LONG:
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint32, nil
+		}
+		return arrow.PrimitiveTypes.Int32, nil
+	case mysql.MYSQL_TYPE_LONGLONG:
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint64, nil
+		}
+		return arrow.PrimitiveTypes.Int64, nil
+	case mysql.MYSQL_TYPE_YEAR:
+		return arrow.PrimitiveTypes.Uint16, nil
+	case mysql.MYSQL_TYPE_BIT:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case mysql.MYSQL_TYPE_FLOAT:
+		return arrow.PrimitiveTypes.Float32, nil
+	case mysql.MYSQL_TYPE_DOUBLE:
+		return arrow.PrimitiveTypes.Float64, nil
+	case mysql.MYSQL_TYPE_DECIMAL, mysql.MYSQL_TYPE_NEWDECIMAL:
+		precision := decimalPrecision(f)
+		if precision < 1 || precision > maxDecimal128Precision {
+			return arrow.BinaryTypes.String, nil
+		}
+		return &arrow.Decimal128Type{Precision: precision, Scale: int32(f.Decimal)}, nil
+	case mysql.MYSQL_TYPE_DATE, mysql.MYSQL_TYPE_NEWDATE:
+		return arrow.FixedWidthTypes.Date32, nil
+	case mysql.MYSQL_TYPE_DATETIME, mysql.MYSQL_TYPE_TIMESTAMP, mysql.MYSQL_TYPE_DATETIME2, mysql.MYSQL_TYPE_TIMESTAMP2:
+		return &arrow.TimestampType{Unit: arrow.Microsecond}, nil
+	case mysql.MYSQL_TYPE_TIME, mysql.MYSQL_TYPE_TIME2:
+		return arrow.FixedWidthTypes.Duration_us, nil
+	case mysql.MYSQL_TYPE_JSON:
+		return arrow.BinaryTypes.String, nil
+	case mysql.MYSQL_TYPE_GEOMETRY, mysql.MYSQL_TYPE_VECTOR:
+		return arrow.BinaryTypes.Binary, nil
+	case mysql.MYSQL_TYPE_VARCHAR, mysql.MYSQL_TYPE_VAR_STRING, mysql.MYSQL_TYPE_STRING,
+		mysql.MYSQL_TYPE_ENUM, mysql.MYSQL_TYPE_SET, mysql.MYSQL_TYPE_TINY_BLOB,
+		mysql.MYSQL_TYPE_MEDIUM_BLOB, mysql.MYSQL_TYPE_LONG_BLOB, mysql.MYSQL_TYPE_BLOB:
+		if f.Charset == mysql.BINARY_COLLATION_ID {
+			return arrow.BinaryTypes.Binary, nil
+		}
+		return arrow.BinaryTypes.String, nil
+	case mysql.MYSQL_TYPE_NULL:
+		return arrow.Null, nil
+	}
+	return nil, fmt.Errorf("unsupported column type %d", f.Type)
+}
+
+// decimalPrecision returns the number of digits of a DECIMAL column. The length of the column
+// also counts the decimal point and the sign.
+func decimalPrecision(f *mysql.Field) int32 {
+	precision := int32(f.ColumnLength)
+	if f.Decimal > 0 {
+		precision--
+	}
+	if f.Flag&mysql.UNSIGNED_FLAG == 0 {
+		precision--
+	}
+	return precision
+}
+
+// appender appends a value that is not NULL to the builder of its column
+type appender func(b array.Builder, v *mysql.FieldValue) error
+
+// newAppender returns the appender for the column f, with the Arrow type tp
+func newAppender(f *mysql.Field, tp arrow.DataType) appender {
+	var appendValue appender
+	switch tp := tp.(type) {
+	case *arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			n, err := asInt64(v)
+			if err != nil {
+				return err
+			}
+			switch b := b.(type) {
+			case *array.Int8Builder:
+				b.Append(int8(n))
+			case *array.Int16Builder:
+				b.Append(int16(n))
+			case *array.Int32Builder:
+				b.Append(int32(n))
+			case *array.Int64Builder:
+				b.Append(n)
+			}
+			return nil
+		}
+	case *arrow.Uint8Type, *arrow.Uint16Type, *arrow.Uint32Type, *arrow.Uint64Type:
+		bit := f.Type == mysql.MYSQL_TYPE_BIT
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			var n uint64
+			var err error
+			if bit {
+				n, err = bitValue(v)
+			} else {
+				n, err = asUint64(v)
+			}
+			if err != nil {
+				return err
+			}
+			switch b := b.(type) {
+			case *array.Uint8Builder:
+				b.Append(uint8(n))
+			case *array.Uint16Builder:
+				b.Append(uint16(n))
+			case *array.Uint32Builder:
+				b.Append(uint32(n))
+			case *array.Uint64Builder:
+				b.Append(n)
+			}
+			return nil
+		}
+	case *arrow.Float32Type:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			x, err := asFloat64(v)
+			if err != nil {
+				return err
+			}
+			b.(*array.Float32Builder).Append(float32(x))
+			return nil
+		}
+	case *arrow.Float64Type:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			x, err := asFloat64(v)
+			if err != nil {
+				return err
+			}
+			b.(*array.Float64Builder).Append(x)
+			return nil
+		}
+	case *arrow.Decimal128Type:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			n, err := decimal128.FromString(string(v.AsString()), tp.Precision, tp.Scale)
+			if err != nil {
+				return err
+			}
+			b.(*array.Decimal128Builder).Append(n)
+			return nil
+		}
+	case *arrow.Date32Type:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			s := string(v.AsString())
+			if isZeroDate(s) {
+				b.AppendNull()
+				return nil
+			}
+			t, err := time.Parse(time.DateOnly, s)
+			if err != nil {
+				return err
+			}
+			b.(*array.Date32Builder).Append(arrow.Date32FromTime(t))
+			return nil
+		}
+	case *arrow.TimestampType:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			s := string(v.AsString())
+			if isZeroDate(s) {
+				b.AppendNull()
+				return nil
+			}
+			t, err := time.Parse("2006-01-02 15:04:05.999999", s)
+			if err != nil {
+				return err
+			}
+			b.(*array.TimestampBuilder).Append(arrow.Timestamp(t.UnixMicro()))
+			return nil
+		}
+	case *arrow.DurationType:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			d, err := parseTime(string(v.AsString()))
+			if err != nil {
+				return err
+			}
+			b.(*array.DurationBuilder).Append(arrow.Duration(d.Microseconds()))
+			return nil
+		}
+	case *arrow.StringType:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			b.(*array.StringBuilder).BinaryBuilder.Append(v.AsString())
+			return nil
+		}
+	case *arrow.BinaryType:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			b.(*array.BinaryBuilder).Append(v.AsString())
+			return nil
+		}
+	case *arrow.NullType:
+		appendValue = func(b array.Builder, v *mysql.FieldValue) error {
+			b.AppendNull()
+			return nil
+		}
+	}
+
+	return func(b array.Builder, v *mysql.FieldValue) error {
+		if v.Type == mysql.FieldValueTypeNull {
+			b.AppendNull()
+			return nil
+		}
+		return appendValue(b, v)
+	}
+}
+
+// asInt64 returns the value of an integer column
+func asInt64(v *mysql.FieldValue) (int64, error) {
+	switch v.Type {
+	case mysql.FieldValueTypeSigned:
+		return v.AsInt64(), nil
+	case mysql.FieldValueTypeUnsigned:
+		return int64(v.AsUint64()), nil
+	}
+	return strconv.ParseInt(string(v.AsString()), 10, 64)
+}
+
+// asUint64 returns the value of an unsigned integer column
+func asUint64(v *mysql.FieldValue) (uint64, error) {
+	switch v.Type {
+	case mysql.FieldValueTypeUnsigned:
+		return v.AsUint64(), nil
+	case mysql.FieldValueTypeSigned:
+		return uint64(v.AsInt64()), nil
+	}
+	return strconv.ParseUint(string(v.AsString()), 10, 64)
+}
+
+// asFloat64 returns the value of a FLOAT or DOUBLE column
+func asFloat64(v *mysql.FieldValue) (float64, error) {
+	if v.Type == mysql.FieldValueTypeFloat {
+		return v.AsFloat64(), nil
+	}
+	return strconv.ParseFloat(string(v.AsString()), 64)
+}
+
+// bitValue returns the value of a BIT column, which is sent as big endian bytes
+func bitValue(v *mysql.FieldValue) (uint64, error) {
+	if v.Type != mysql.FieldValueTypeString {
+		return asUint64(v)
+	}
+	b := v.AsString()
+	if len(b) > 8 {
+		return 0, fmt.Errorf("BIT value of %d bytes", len(b))
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// isZeroDate returns true for a date or datetime that has a zero year, month or day, like
+// 0000-00-00 or 2024-00-00, which the server allows without NO_ZERO_DATE and NO_ZERO_IN_DATE
+func isZeroDate(s string) bool {
+	return len(s) >= 10 && (s[:4] == "0000" || s[5:7] == "00" || s[8:10] == "00")
+}
+
+// parseTime parses a TIME value like -838:59:59.000000
+func parseTime(s string) (time.Duration, error) {
+	text, negative := strings.CutPrefix(s, "-")
+	hms, frac, _ := strings.Cut(text, ".")
+	parts := strings.Split(hms, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid TIME value %q", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.ParseUint(parts[i], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid TIME value %q", s)
+		}
+		d += time.Duration(n) * unit
+	}
+	if frac != "" {
+		if len(frac) > 6 {
+			return 0, fmt.Errorf("invalid TIME value %q", s)
+		}
+		micros, err := strconv.ParseUint(frac+strings.Repeat("0", 6-len(frac)), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid TIME value %q", s)
+		}
+		d += time.Duration(micros) * time.Microsecond
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}